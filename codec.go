@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Every encoded Rational starts with a version byte and a format byte,
+// followed by the format's payload. Version 1 stores the numerator and
+// denominator as 64-bit integers; a later representation must bump the
+// version and keep decoding version 1 so persisted values stay readable.
+const codecVersion byte = 1
+
+type Format byte
+
+const (
+	FormatText   Format = 't' // "numerator/denominator"
+	FormatBinary Format = 'b' // two signed varints
+	FormatJSON   Format = 'j' // {"numerator":n,"denominator":d}
+)
+
+// Formats lists every format this version can encode and decode, in order
+// of preference.
+var Formats = []Format{FormatBinary, FormatJSON, FormatText}
+
+func (f Format) String() string {
+	switch f {
+	case FormatText:
+		return "text"
+	case FormatBinary:
+		return "binary"
+	case FormatJSON:
+		return "json"
+	default:
+		return fmt.Sprintf("Format(%d)", byte(f))
+	}
+}
+
+type jsonRational struct {
+	Numerator   int64 `json:"numerator"`
+	Denominator int64 `json:"denominator"`
+}
+
+// Encode serializes r in format f. The numerator and denominator are stored
+// as given, not reduced. The error is non-nil if the format is unknown or
+// the denominator is zero.
+func Encode(r Rationalizer, f Format) ([]byte, error) {
+	a, b := r.Split()
+	if b == 0 {
+		return nil, errors.New("denominator cannot be zero")
+	}
+	out := []byte{codecVersion, byte(f)}
+
+	switch f {
+	case FormatText:
+		return fmt.Appendf(out, "%v/%v", a, b), nil
+	case FormatBinary:
+		out = binary.AppendVarint(out, int64(a))
+		return binary.AppendVarint(out, int64(b)), nil
+	case FormatJSON:
+		payload, err := json.Marshal(jsonRational{int64(a), int64(b)})
+		if err != nil {
+			return nil, err
+		}
+		return append(out, payload...), nil
+	default:
+		return nil, fmt.Errorf("unknown format %v", f)
+	}
+}
+
+// Decode parses a value written by Encode with this or any earlier codec
+// version, whatever its format.
+func Decode(data []byte) (Rationalizer, error) {
+	if len(data) < 2 {
+		return nil, errors.New("encoded rational is too short")
+	}
+	if data[0] == 0 || data[0] > codecVersion {
+		return nil, fmt.Errorf("unsupported codec version %v", data[0])
+	}
+	f, payload := Format(data[1]), data[2:]
+
+	var a, b int64
+	switch f {
+	case FormatText:
+		num, den, ok := strings.Cut(string(payload), "/")
+		if !ok {
+			return nil, fmt.Errorf("malformed text rational %q", payload)
+		}
+		var err error
+		if a, err = parseCanonicalInt(num); err != nil {
+			return nil, err
+		}
+		if b, err = parseCanonicalInt(den); err != nil {
+			return nil, err
+		}
+	case FormatBinary:
+		var n, m int
+		a, n = binary.Varint(payload)
+		if n <= 0 {
+			return nil, errors.New("malformed binary numerator")
+		}
+		b, m = binary.Varint(payload[n:])
+		if m <= 0 || n+m != len(payload) {
+			return nil, errors.New("malformed binary denominator")
+		}
+	case FormatJSON:
+		var j jsonRational
+		dec := json.NewDecoder(bytes.NewReader(payload))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&j); err != nil {
+			return nil, err
+		}
+		if dec.InputOffset() != int64(len(payload)) {
+			return nil, errors.New("trailing data after json rational")
+		}
+		a, b = j.Numerator, j.Denominator
+	default:
+		return nil, fmt.Errorf("unknown format %v", f)
+	}
+
+	if b == 0 {
+		return nil, errors.New("denominator cannot be zero")
+	}
+	if int64(int(a)) != a || int64(int(b)) != b {
+		return nil, errors.New("encoded rational overflows int")
+	}
+	return Rational{int(a), int(b)}, nil
+}
+
+// parseCanonicalInt parses s as Encode writes integers, so each value has
+// exactly one text form: no sign other than "-", no leading zeros, no "-0".
+func parseCanonicalInt(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if strconv.FormatInt(n, 10) != s {
+		return 0, fmt.Errorf("non-canonical integer %q", s)
+	}
+	return n, nil
+}
+
+// Negotiate returns the first format in ours that the peer also supports.
+// The error is non-nil if there is no format in common.
+func Negotiate(ours, theirs []Format) (Format, error) {
+	for _, f := range ours {
+		for _, g := range theirs {
+			if f == g {
+				return f, nil
+			}
+		}
+	}
+	return 0, errors.New("no common format")
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+var codecValues = []Rational{
+	{0, 1},
+	{1, 2},
+	{2, -4}, // unreduced, negative denominator
+	{-3, 7},
+	{-6, -9},
+	{math.MaxInt64, 1},
+	{math.MinInt64, 1},
+	{1, math.MaxInt64},
+	{1, math.MinInt64},
+	{math.MaxInt64, math.MinInt64},
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	for _, f := range Formats {
+		for _, r := range codecValues {
+			data, err := Encode(r, f)
+			if err != nil {
+				t.Errorf("Encode(%v, %v) error: %v", r, f, err)
+				continue
+			}
+			got, err := Decode(data)
+			if err != nil {
+				t.Errorf("Decode(Encode(%v, %v)) error: %v", r, f, err)
+				continue
+			}
+			// Values are stored as given, not just by value.
+			if got != r {
+				t.Errorf("Decode(Encode(%v, %v)) = %v", r, f, got)
+			}
+		}
+	}
+}
+
+func TestCodecCrossFormat(t *testing.T) {
+	for _, r := range codecValues {
+		var decoded []Rationalizer
+		for _, f := range Formats {
+			data, err := Encode(r, f)
+			if err != nil {
+				t.Fatalf("Encode(%v, %v) error: %v", r, f, err)
+			}
+			got, err := Decode(data)
+			if err != nil {
+				t.Fatalf("Decode(Encode(%v, %v)) error: %v", r, f, err)
+			}
+			decoded = append(decoded, got)
+		}
+		for i := 1; i < len(decoded); i++ {
+			if decoded[i] != decoded[0] {
+				t.Errorf("%v decodes as %v in %v but %v in %v", r, decoded[i], Formats[i], decoded[0], Formats[0])
+			}
+		}
+	}
+}
+
+// Version 1 encodings must stay readable. Do not change these bytes; a new
+// representation needs a new version and new golden entries.
+var codecGolden = []struct {
+	data []byte
+	want Rational
+}{
+	{[]byte("\x01t2/-4"), Rational{2, -4}},
+	{[]byte("\x01b\x04\x07"), Rational{2, -4}},
+	{[]byte("\x01j{\"numerator\":2,\"denominator\":-4}"), Rational{2, -4}},
+	{[]byte("\x01t-9223372036854775808/9223372036854775807"), Rational{math.MinInt64, math.MaxInt64}},
+	{[]byte("\x01b\xff\xff\xff\xff\xff\xff\xff\xff\xff\x01\xfe\xff\xff\xff\xff\xff\xff\xff\xff\x01"), Rational{math.MinInt64, math.MaxInt64}},
+	{[]byte("\x01j{\"numerator\":-9223372036854775808,\"denominator\":9223372036854775807}"), Rational{math.MinInt64, math.MaxInt64}},
+	{[]byte("\x01b\x00\x02"), Rational{0, 1}},
+}
+
+func TestCodecGoldenV1(t *testing.T) {
+	for _, g := range codecGolden {
+		got, err := Decode(g.data)
+		if err != nil || got != g.want {
+			t.Errorf("Decode(%q) = %v, %v; want %v", g.data, got, err, g.want)
+		}
+		data, err := Encode(g.want, Format(g.data[1]))
+		if err != nil || !bytes.Equal(data, g.data) {
+			t.Errorf("Encode(%v, %v) = %q, %v; want %q", g.want, Format(g.data[1]), data, err, g.data)
+		}
+	}
+}
+
+func TestCodecDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"header only version", []byte{1}},
+		{"version 0", []byte("\x00t1/2")},
+		{"future version", []byte("\x02t1/2")},
+		{"unknown format", []byte("\x01z1/2")},
+		{"text zero denominator", []byte("\x01t1/0")},
+		{"text no slash", []byte("\x01t12")},
+		{"text bad numerator", []byte("\x01ta/2")},
+		{"text empty denominator", []byte("\x01t1/")},
+		{"text two slashes", []byte("\x01t1/2/3")},
+		{"text out of range", []byte("\x01t9223372036854775808/1")},
+		{"text plus sign", []byte("\x01t+1/2")},
+		{"text plus denominator", []byte("\x01t1/+2")},
+		{"text leading zero", []byte("\x01t01/2")},
+		{"text negative zero", []byte("\x01t-0/2")},
+		{"text space", []byte("\x01t 1/2")},
+		{"binary empty", []byte("\x01b")},
+		{"binary truncated", []byte("\x01b\x04")},
+		{"binary truncated varint", []byte("\x01b\x04\xff")},
+		{"binary trailing byte", []byte("\x01b\x04\x07\x00")},
+		{"binary zero denominator", []byte("\x01b\x02\x00")},
+		{"json truncated", []byte("\x01j{\"numerator\":1")},
+		{"json wrong type", []byte("\x01j{\"numerator\":\"1\",\"denominator\":2}")},
+		{"json missing denominator", []byte("\x01j{\"numerator\":1}")},
+		{"json zero denominator", []byte("\x01j{\"numerator\":1,\"denominator\":0}")},
+		{"json unknown field", []byte("\x01j{\"numerator\":1,\"denominator\":2,\"extra\":3}")},
+		{"json trailing data", []byte("\x01j{\"numerator\":1,\"denominator\":2}{}")},
+	}
+	for _, tt := range tests {
+		if got, err := Decode(tt.data); err == nil {
+			t.Errorf("%v: Decode(%q) = %v; want error", tt.name, tt.data, got)
+		}
+	}
+}
+
+func TestCodecEncodeErrors(t *testing.T) {
+	for _, f := range Formats {
+		if data, err := Encode(Rational{1, 0}, f); err == nil {
+			t.Errorf("Encode(1/0, %v) = %q; want error", f, data)
+		}
+	}
+	if data, err := Encode(Rational{1, 2}, Format('z')); err == nil {
+		t.Errorf("Encode(1/2, 'z') = %q; want error", data)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		ours, theirs []Format
+		want         Format
+		ok           bool
+	}{
+		{Formats, Formats, FormatBinary, true},
+		{[]Format{FormatJSON, FormatText}, Formats, FormatJSON, true},
+		{Formats, []Format{FormatText}, FormatText, true},
+		{[]Format{FormatJSON}, []Format{FormatText}, 0, false},
+		{nil, Formats, 0, false},
+	}
+	for _, tt := range tests {
+		got, err := Negotiate(tt.ours, tt.theirs)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("Negotiate(%v, %v) = %v, %v; want %v", tt.ours, tt.theirs, got, err, tt.want)
+		}
+	}
+}
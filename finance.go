@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// One row of an amortization schedule. All amounts are exact; the
+// denominators grow like (1+rate)^n, so they are kept in math/big.
+type Payment struct {
+	Period    int
+	Payment   *big.Rat
+	Interest  *big.Rat
+	Principal *big.Rat
+	Balance   *big.Rat
+}
+
+// Amortize returns the exact level-payment schedule for a loan of principal
+// at the given periodic rate over n periods. The payment is
+// principal*rate*(1+rate)^n / ((1+rate)^n - 1), or principal/n when the
+// rate is zero, so the final balance is exactly zero. The error is non-nil
+// if the arguments are invalid.
+func Amortize(principal, rate Rationalizer, n int) ([]Payment, error) {
+	if n <= 0 {
+		return nil, errors.New("number of periods must be positive")
+	}
+	if principal.Denominator() == 0 || rate.Denominator() == 0 {
+		return nil, errors.New("denominator cannot be zero")
+	}
+	bp, br := toBig(principal), toBig(rate)
+	if br.Sign() < 0 {
+		return nil, errors.New("rate cannot be negative")
+	}
+
+	pay := new(big.Rat)
+	if br.Sign() == 0 {
+		pay.Quo(bp, big.NewRat(int64(n), 1))
+	} else {
+		growth := new(big.Rat).SetInt64(1)
+		factor := new(big.Rat).Add(big.NewRat(1, 1), br)
+		for i := 0; i < n; i++ {
+			growth.Mul(growth, factor)
+		}
+		pay.Mul(bp, br)
+		pay.Mul(pay, growth)
+		pay.Quo(pay, growth.Sub(growth, big.NewRat(1, 1)))
+	}
+
+	schedule := make([]Payment, 0, n)
+	balance := new(big.Rat).Set(bp)
+	for i := 1; i <= n; i++ {
+		interest := new(big.Rat).Mul(balance, br)
+		principalPart := new(big.Rat).Sub(pay, interest)
+		balance = new(big.Rat).Sub(balance, principalPart)
+		schedule = append(schedule, Payment{
+			Period:    i,
+			Payment:   new(big.Rat).Set(pay),
+			Interest:  interest,
+			Principal: principalPart,
+			Balance:   balance,
+		})
+	}
+	return schedule, nil
+}
+
+// How Round resolves values that are not already integers.
+type RoundingMode int
+
+const (
+	RoundHalfAwayFromZero RoundingMode = iota // 2.5 -> 3, -2.5 -> -3
+	RoundHalfEven                             // 2.5 -> 2, 3.5 -> 4
+	RoundTowardZero                           // 2.9 -> 2, -2.9 -> -2
+	RoundAwayFromZero                         // 2.1 -> 3, -2.1 -> -3
+)
+
+// Round returns x rounded to an integer using mode.
+func Round(x *big.Rat, mode RoundingMode) *big.Int {
+	q, m := new(big.Int).QuoRem(x.Num(), x.Denom(), new(big.Int))
+	if m.Sign() == 0 {
+		return q
+	}
+	away := big.NewInt(int64(x.Sign()))
+	twice := new(big.Int).Mul(m.Abs(m), big.NewInt(2))
+	half := twice.Cmp(x.Denom())
+
+	switch mode {
+	case RoundHalfAwayFromZero:
+		if half >= 0 {
+			q.Add(q, away)
+		}
+	case RoundHalfEven:
+		if half > 0 || (half == 0 && q.Bit(0) == 1) {
+			q.Add(q, away)
+		}
+	case RoundAwayFromZero:
+		q.Add(q, away)
+	}
+	return q
+}
+
+// RoundCents returns x rounded to a whole number of cents using mode.
+func RoundCents(x *big.Rat, mode RoundingMode) *big.Int {
+	return Round(new(big.Rat).Mul(x, big.NewRat(100, 1)), mode)
+}
+
+// AllocateCents rounds each amount to cents so that the rounded amounts add
+// up to the rounded total. Each share is the difference of the rounded
+// running totals, so rounding remainders carry into later entries instead
+// of accumulating.
+func AllocateCents(amounts []*big.Rat, mode RoundingMode) []*big.Int {
+	shares := make([]*big.Int, len(amounts))
+	running := new(big.Rat)
+	prev := new(big.Int)
+	for i, x := range amounts {
+		running.Add(running, x)
+		cur := RoundCents(running, mode)
+		shares[i] = new(big.Int).Sub(cur, prev)
+		prev = cur
+	}
+	return shares
+}
+
+// One row of a schedule rounded to cents.
+type RoundedPayment struct {
+	Period    int
+	Payment   *big.Int
+	Interest  *big.Int
+	Principal *big.Int
+	Balance   *big.Int
+}
+
+// RoundSchedule rounds an exact schedule to cents for display. Principal
+// and interest are allocated with AllocateCents, so the principal column
+// adds up to the rounded loan amount and the last balance is zero.
+func RoundSchedule(schedule []Payment, mode RoundingMode) []RoundedPayment {
+	if len(schedule) == 0 {
+		return nil
+	}
+	interest := make([]*big.Rat, len(schedule))
+	principal := make([]*big.Rat, len(schedule))
+	for i, p := range schedule {
+		interest[i], principal[i] = p.Interest, p.Principal
+	}
+	interestCents := AllocateCents(interest, mode)
+	principalCents := AllocateCents(principal, mode)
+
+	loan := new(big.Rat).Add(schedule[0].Balance, schedule[0].Principal)
+	balance := RoundCents(loan, mode)
+	rows := make([]RoundedPayment, len(schedule))
+	for i, p := range schedule {
+		balance = new(big.Int).Sub(balance, principalCents[i])
+		rows[i] = RoundedPayment{
+			Period:    p.Period,
+			Payment:   new(big.Int).Add(interestCents[i], principalCents[i]),
+			Interest:  interestCents[i],
+			Principal: principalCents[i],
+			Balance:   balance,
+		}
+	}
+	return rows
+}
+
+// FormatCents renders a whole number of cents with two decimals.
+func FormatCents(c *big.Int) string {
+	sign := ""
+	if c.Sign() < 0 {
+		sign = "-"
+	}
+	units, cents := new(big.Int).QuoRem(new(big.Int).Abs(c), big.NewInt(100), new(big.Int))
+	return fmt.Sprintf("%v%v.%02d", sign, units, cents.Int64())
+}
+
+// Cents renders r as a currency amount with two decimals, rounded using
+// mode. The error is non-nil if r has a zero denominator.
+func Cents(r Rationalizer, mode RoundingMode) (string, error) {
+	if r.Denominator() == 0 {
+		return "", errors.New("denominator cannot be zero")
+	}
+	return FormatCents(RoundCents(toBig(r), mode)), nil
+}
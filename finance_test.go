@@ -0,0 +1,134 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestAmortizeExact(t *testing.T) {
+	for _, tt := range []struct {
+		principal, rate Rational
+		n               int
+	}{
+		{Rational{1000, 1}, Rational{1, 10}, 3},
+		{Rational{1000, 1}, Rational{1, 100}, 12},
+		{Rational{250000, 1}, Rational{1, 200}, 360},
+		{Rational{1000, 1}, Rational{0, 1}, 7},
+	} {
+		schedule, err := Amortize(tt.principal, tt.rate, tt.n)
+		if err != nil {
+			t.Fatalf("Amortize(%v, %v, %v) error: %v", tt.principal, tt.rate, tt.n, err)
+		}
+		if len(schedule) != tt.n {
+			t.Fatalf("Amortize(%v, %v, %v) has %v rows", tt.principal, tt.rate, tt.n, len(schedule))
+		}
+		paid := new(big.Rat)
+		for _, p := range schedule {
+			paid.Add(paid, p.Principal)
+		}
+		if paid.Cmp(toBig(tt.principal)) != 0 {
+			t.Errorf("Amortize(%v, %v, %v) repays %v", tt.principal, tt.rate, tt.n, paid.RatString())
+		}
+		if last := schedule[tt.n-1].Balance; last.Sign() != 0 {
+			t.Errorf("Amortize(%v, %v, %v) final balance %v", tt.principal, tt.rate, tt.n, last.RatString())
+		}
+	}
+}
+
+func TestAmortizeErrors(t *testing.T) {
+	for _, tt := range []struct {
+		principal, rate Rational
+		n               int
+	}{
+		{Rational{1000, 1}, Rational{1, 10}, 0},
+		{Rational{1000, 0}, Rational{1, 10}, 3},
+		{Rational{1000, 1}, Rational{1, 0}, 3},
+		{Rational{1000, 1}, Rational{-1, 10}, 3},
+	} {
+		if _, err := Amortize(tt.principal, tt.rate, tt.n); err == nil {
+			t.Errorf("Amortize(%v, %v, %v): want error", tt.principal, tt.rate, tt.n)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		x    *big.Rat
+		mode RoundingMode
+		want int64
+	}{
+		{big.NewRat(5, 2), RoundHalfAwayFromZero, 3},
+		{big.NewRat(-5, 2), RoundHalfAwayFromZero, -3},
+		{big.NewRat(5, 2), RoundHalfEven, 2},
+		{big.NewRat(7, 2), RoundHalfEven, 4},
+		{big.NewRat(-5, 2), RoundHalfEven, -2},
+		{big.NewRat(26, 10), RoundHalfEven, 3},
+		{big.NewRat(29, 10), RoundTowardZero, 2},
+		{big.NewRat(-29, 10), RoundTowardZero, -2},
+		{big.NewRat(21, 10), RoundAwayFromZero, 3},
+		{big.NewRat(-21, 10), RoundAwayFromZero, -3},
+		{big.NewRat(4, 1), RoundAwayFromZero, 4},
+	}
+	for _, tt := range tests {
+		if got := Round(tt.x, tt.mode); got.Int64() != tt.want {
+			t.Errorf("Round(%v, %v) = %v; want %v", tt.x, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestAllocateCents(t *testing.T) {
+	third := big.NewRat(1, 3)
+	got := AllocateCents([]*big.Rat{third, third, third}, RoundHalfEven)
+	want := []int64{33, 34, 33}
+	for i := range want {
+		if got[i].Int64() != want[i] {
+			t.Fatalf("AllocateCents(1/3 x3) = %v; want %v", got, want)
+		}
+	}
+}
+
+func TestRoundScheduleAddsUp(t *testing.T) {
+	for _, mode := range []RoundingMode{RoundHalfAwayFromZero, RoundHalfEven, RoundTowardZero, RoundAwayFromZero} {
+		schedule, err := Amortize(Rational{1000, 1}, Rational{1, 100}, 12)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows := RoundSchedule(schedule, mode)
+		total := new(big.Int)
+		for _, r := range rows {
+			total.Add(total, r.Principal)
+			if sum := new(big.Int).Add(r.Interest, r.Principal); sum.Cmp(r.Payment) != 0 {
+				t.Errorf("mode %v period %v: payment %v != interest + principal", mode, r.Period, r.Payment)
+			}
+		}
+		if total.Int64() != 100000 {
+			t.Errorf("mode %v: principal column adds up to %v cents", mode, total)
+		}
+		if last := rows[len(rows)-1].Balance; last.Sign() != 0 {
+			t.Errorf("mode %v: final balance %v cents", mode, last)
+		}
+	}
+}
+
+func TestCents(t *testing.T) {
+	tests := []struct {
+		r    Rational
+		mode RoundingMode
+		want string
+	}{
+		{Rational{-1, 200}, RoundHalfAwayFromZero, "-0.01"},
+		{Rational{-1, 200}, RoundHalfEven, "0.00"},
+		{Rational{1, 3}, RoundHalfAwayFromZero, "0.33"},
+		{Rational{1, 3}, RoundAwayFromZero, "0.34"},
+		{Rational{-5, 1}, RoundHalfEven, "-5.00"},
+		{Rational{123456789, 100}, RoundTowardZero, "1234567.89"},
+	}
+	for _, tt := range tests {
+		if got, err := Cents(tt.r, tt.mode); err != nil || got != tt.want {
+			t.Errorf("Cents(%v, %v) = %q, %v; want %q", tt.r, tt.mode, got, err, tt.want)
+		}
+	}
+	if _, err := Cents(Rational{1, 0}, RoundHalfEven); err == nil {
+		t.Errorf("Cents(1/0): want error")
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RationalEqualMatcher compares Rationalizers by value rather than by
+// struct equality, so 2/4, 1/2 and -1/-2 all match each other. It satisfies
+// the gomock Matcher and GotFormatter interfaces without importing gomock.
+type RationalEqualMatcher struct {
+	want Rationalizer
+}
+
+// RationalMatcher returns a matcher for arguments equal in value to want.
+func RationalMatcher(want Rationalizer) RationalEqualMatcher {
+	return RationalEqualMatcher{want}
+}
+
+// Matches reports whether x is a Rationalizer equal in value to want.
+func (m RationalEqualMatcher) Matches(x interface{}) bool {
+	got, ok := x.(Rationalizer)
+	if !ok {
+		return false
+	}
+	return sameValue(m.want, got)
+}
+
+func (m RationalEqualMatcher) String() string {
+	return "is equal to " + describe(m.want)
+}
+
+// Got formats the actual argument in the same form as String.
+func (m RationalEqualMatcher) Got(x interface{}) string {
+	if got, ok := x.(Rationalizer); ok {
+		return describe(got)
+	}
+	return fmt.Sprintf("%v (%T)", x, x)
+}
+
+// TestingT is the subset of testing.TB used by AssertRationalEqual. It
+// matches testify's assert.TestingT, so either can be passed.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// AssertRationalEqual reports an error through t unless got equals want in
+// value, in the style of testify's assert functions. It returns whether
+// the values were equal.
+func AssertRationalEqual(t TestingT, want, got Rationalizer, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	if sameValue(want, got) {
+		return true
+	}
+	msg := ""
+	if len(msgAndArgs) > 0 {
+		if format, ok := msgAndArgs[0].(string); ok {
+			msg = "\n" + fmt.Sprintf(format, msgAndArgs[1:]...)
+		} else {
+			msg = "\n" + fmt.Sprint(msgAndArgs...)
+		}
+	}
+	t.Errorf("not equal:\n\twant: %v\n\tgot:  %v%v", describe(want), describe(got), msg)
+	return false
+}
+
+// isNil reports whether r is a nil pointer such as a nil *Rational, which
+// satisfies Rationalizer but panics when its methods are called.
+func isNil(r Rationalizer) bool {
+	v := reflect.ValueOf(r)
+	return !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil())
+}
+
+// sameValue reports whether a and b denote the same rational number. A nil
+// value equals nothing.
+func sameValue(a, b Rationalizer) bool {
+	if isNil(a) || isNil(b) {
+		return false
+	}
+	if a.Denominator() == 0 || b.Denominator() == 0 {
+		return canonical(a) == canonical(b)
+	}
+	return toBig(a).Cmp(toBig(b)) == 0
+}
+
+// describe renders r as fraction, lowest terms and decimal, e.g.
+// "2/4 (= 1/2 ≈ 0.5)".
+func describe(r Rationalizer) string {
+	if isNil(r) {
+		return fmt.Sprintf("nil %T", r)
+	}
+	if r.Denominator() == 0 {
+		return fmt.Sprintf("%v (undefined)", r)
+	}
+	f, _ := toBig(r).Float64()
+	return fmt.Sprintf("%v (= %v ≈ %g)", r, canonical(r), f)
+}
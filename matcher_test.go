@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRationalMatcher(t *testing.T) {
+	m := RationalMatcher(Rational{2, 4})
+	tests := []struct {
+		x    interface{}
+		want bool
+	}{
+		{Rational{1, 2}, true},
+		{Rational{-1, -2}, true},
+		{Rational{2, 4}, true},
+		{Rational{1, 3}, false},
+		{Rational{-1, 2}, false},
+		{Rational{1, 0}, false},
+		{"1/2", false},
+		{nil, false},
+		{(*Rational)(nil), false},
+		{&Rational{1, 2}, true},
+	}
+	for _, tt := range tests {
+		if got := m.Matches(tt.x); got != tt.want {
+			t.Errorf("RationalMatcher(2/4).Matches(%v) = %v; want %v", tt.x, got, tt.want)
+		}
+	}
+
+	if got, want := m.String(), "is equal to 2/4 (= 1/2 ≈ 0.5)"; got != want {
+		t.Errorf("String() = %q; want %q", got, want)
+	}
+	if got, want := m.Got(Rational{3, -6}), "3/-6 (= -1/2 ≈ -0.5)"; got != want {
+		t.Errorf("Got() = %q; want %q", got, want)
+	}
+	if got, want := m.Got((*Rational)(nil)), "nil *main.Rational"; got != want {
+		t.Errorf("Got() = %q; want %q", got, want)
+	}
+	if got, want := m.Got(7), "7 (int)"; got != want {
+		t.Errorf("Got() = %q; want %q", got, want)
+	}
+}
+
+// recorder captures failures reported through TestingT.
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertRationalEqual(t *testing.T) {
+	var r recorder
+	if !AssertRationalEqual(&r, Rational{1, 2}, Rational{-3, -6}) || len(r.errors) != 0 {
+		t.Errorf("AssertRationalEqual(1/2, -3/-6) failed: %v", r.errors)
+	}
+
+	if AssertRationalEqual(&r, Rational{1, 2}, (*Rational)(nil)) || len(r.errors) != 1 {
+		t.Errorf("AssertRationalEqual(1/2, nil) passed: %v", r.errors)
+	}
+	r.errors = nil
+
+	if AssertRationalEqual(&r, Rational{1, 2}, Rational{2, 3}, "case 7") {
+		t.Errorf("AssertRationalEqual(1/2, 2/3) = true")
+	}
+	if len(r.errors) != 1 {
+		t.Fatalf("got %v errors; want 1", len(r.errors))
+	}
+	for _, want := range []string{"1/2 (= 1/2 ≈ 0.5)", "2/3 (= 2/3 ≈ 0.6666666666666666)", "case 7"} {
+		if !strings.Contains(r.errors[0], want) {
+			t.Errorf("error %q does not contain %q", r.errors[0], want)
+		}
+	}
+}
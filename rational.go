@@ -2,8 +2,13 @@ package main
 
 import (
 	"errors"
+	"flag"
 	"fmt"
+	"iter"
+	"math"
+	"math/big"
 	"math/rand"
+	"os"
 	"time"
 )
 
@@ -86,6 +91,41 @@ func (r Rational) toFloat64() float64 {
 	return float64(r.numerator) / float64(r.denominator)
 }
 
+// Float64Report returns the float64 nearest to r, the rounding error f - r,
+// and whether the conversion was exact. err is 0/0 and exact is false if
+// the error does not fit in an int or r has a zero denominator; use
+// Float64ReportBig for the exact error.
+func (r Rational) Float64Report() (f float64, err Rational, exact bool) {
+	f, bigErr, exact := r.Float64ReportBig()
+	if bigErr == nil {
+		return f, Rational{0, 0}, false
+	}
+	err, ok := fromBig(bigErr)
+	if !ok {
+		return f, Rational{0, 0}, false
+	}
+	return f, err, exact
+}
+
+// Float64ReportBig is Float64Report with the rounding error f - r as a
+// big.Rat, so it is always exact. err is nil if r has a zero denominator
+// or f is infinite.
+func (r Rational) Float64ReportBig() (f float64, err *big.Rat, exact bool) {
+	if r.denominator == 0 {
+		return r.toFloat64(), nil, false
+	}
+	x := toBig(r)
+	f, exact = x.Float64()
+	if exact {
+		return f, new(big.Rat), true
+	}
+	err = new(big.Rat).SetFloat64(f)
+	if err == nil {
+		return f, nil, false
+	}
+	return f, err.Sub(err, x), false
+}
+
 // 7.
 func (r Rational) Equal(other Rationalizer) bool {
 	r_gdc := GCD(r.numerator, r.denominator)
@@ -108,6 +148,96 @@ func GCD(m, n int) int {
 	return m
 }
 
+// LCM. The error is non-nil if the result does not fit in an int.
+func LCM(m, n int) (int, error) {
+	l := bigLCM(big.NewInt(int64(m)), big.NewInt(int64(n)))
+	if !fitsInt(l) {
+		return 0, fmt.Errorf("lcm of %v and %v overflows int", m, n)
+	}
+	return int(l.Int64()), nil
+}
+
+// bigLCM returns the non-negative lcm of x and y, or 0 if either is 0.
+func bigLCM(x, y *big.Int) *big.Int {
+	if x.Sign() == 0 || y.Sign() == 0 {
+		return new(big.Int)
+	}
+	l := new(big.Int).GCD(nil, nil, x, y)
+	l.Quo(x, l)
+	l.Mul(l, y)
+	return l.Abs(l)
+}
+
+func fitsInt(x *big.Int) bool {
+	return x.IsInt64() && x.Int64() == int64(int(x.Int64()))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// canonical returns r in lowest terms with a positive denominator.
+func canonical(r Rationalizer) Rational {
+	a, b := r.Split()
+	gcd := abs(GCD(a, b))
+	if gcd == 0 {
+		return Rational{a, b}
+	}
+	a, b = a/gcd, b/gcd
+	if b < 0 {
+		a, b = -a, -b
+	}
+	return Rational{a, b}
+}
+
+// GCD of rationals: the largest rational that divides every value in rs
+// an integer number of times, i.e. gcd of numerators over lcm of
+// denominators. Returns 0/1 for an empty slice. The error is non-nil if a
+// value has a zero denominator or the result does not fit in a Rational.
+func GCDRational(rs []Rationalizer) (Rationalizer, error) {
+	a, b := new(big.Int), big.NewInt(1)
+	for _, r := range rs {
+		if r.Denominator() == 0 {
+			return Rational{0, 1}, errors.New("denominator cannot be zero")
+		}
+		a.GCD(nil, nil, a, big.NewInt(int64(r.Numerator())))
+		b = bigLCM(b, big.NewInt(int64(r.Denominator())))
+	}
+	return ratFromInts(a, b)
+}
+
+// LCM of rationals: the smallest positive rational that every value in rs
+// divides an integer number of times, i.e. lcm of numerators over gcd of
+// denominators. Returns 0/1 for an empty slice or if any value is zero.
+// The error is non-nil if a value has a zero denominator or the result
+// does not fit in a Rational.
+func LCMRational(rs []Rationalizer) (Rationalizer, error) {
+	if len(rs) == 0 {
+		return Rational{0, 1}, nil
+	}
+	a, b := big.NewInt(1), new(big.Int)
+	for _, r := range rs {
+		if r.Denominator() == 0 {
+			return Rational{0, 1}, errors.New("denominator cannot be zero")
+		}
+		a = bigLCM(a, big.NewInt(int64(r.Numerator())))
+		b.GCD(nil, nil, b, big.NewInt(int64(r.Denominator())))
+	}
+	return ratFromInts(a, b)
+}
+
+// ratFromInts returns a/b in lowest terms, or an error if it overflows.
+func ratFromInts(a, b *big.Int) (Rationalizer, error) {
+	r, ok := fromBig(new(big.Rat).SetFrac(a, b))
+	if !ok {
+		return Rational{0, 1}, fmt.Errorf("%v/%v overflows int", a, b)
+	}
+	return r, nil
+}
+
 // 8.
 func (r Rational) LessThan(other Rationalizer) bool {
 	if r.toFloat64() < other.toFloat64() {
@@ -180,12 +310,213 @@ func HarmonicSum(n int) Rationalizer {
 	var sum Rationalizer
 	sum = Rational{1, 1}
 
-	for i := 2; i <= n; i++ {
-		sum = sum.Add(Rational{1, i})
+	for h := range HarmonicPartialSums(n) {
+		sum = h
 	}
 	return sum
 }
 
+// Harmonic partial sums H(1), H(2), ..., H(n), computed lazily.
+func HarmonicPartialSums(n int) iter.Seq[Rationalizer] {
+	return func(yield func(Rationalizer) bool) {
+		var sum Rationalizer
+		sum = Rational{0, 1}
+
+		for i := 1; i <= n; i++ {
+			sum = sum.Add(Rational{1, i})
+			if !yield(sum) {
+				return
+			}
+		}
+	}
+}
+
+// Farey sequence of order n: every fraction in lowest terms between 0/1
+// and 1/1 with denominator at most n, in increasing order.
+func Farey(n int) iter.Seq[Rationalizer] {
+	return func(yield func(Rationalizer) bool) {
+		if n < 1 {
+			return
+		}
+		a, b, c, d := 0, 1, 1, n
+		if !yield(Rational{a, b}) {
+			return
+		}
+		for c <= n {
+			k := (n + b) / d
+			a, b, c, d = c, d, k*c-a, k*d-b
+			if !yield(Rational{a, b}) {
+				return
+			}
+		}
+	}
+}
+
+// Calkin-Wilf sequence 1/1, 1/2, 2/1, 1/3, 3/2, ... which lists every
+// positive rational exactly once. The sequence is infinite; stop ranging
+// over it to end it.
+func CalkinWilf() iter.Seq[Rationalizer] {
+	return func(yield func(Rationalizer) bool) {
+		a, b := 1, 1
+		for yield(Rational{a, b}) {
+			// next = 1 / (2*floor(a/b) - a/b + 1)
+			a, b = b, 2*(a/b)*b-a+b
+		}
+	}
+}
+
+// Continued fraction convergents of r, ending with r itself in lowest
+// terms. Yields nothing if r has a zero denominator.
+func Convergents(r Rationalizer) iter.Seq[Rationalizer] {
+	return func(yield func(Rationalizer) bool) {
+		c := canonical(r)
+		a, b := c.numerator, c.denominator
+		if b == 0 {
+			return
+		}
+		h, hPrev := 1, 0
+		k, kPrev := 0, 1
+		for b != 0 {
+			q := a / b
+			if a%b < 0 {
+				q--
+			}
+			h, hPrev = q*h+hPrev, h
+			k, kPrev = q*k+kPrev, k
+			if !yield(Rational{h, k}) {
+				return
+			}
+			a, b = b, a-q*b
+		}
+	}
+}
+
+// Linspace yields n evenly spaced values from start to stop inclusive, in
+// lowest terms with a positive denominator. A single value is just start.
+// Values are computed in math/big; the sequence stops early at the first
+// value that does not fit in a Rational. Yields nothing if start or stop
+// has a zero denominator.
+func Linspace(start, stop Rationalizer, n int) iter.Seq[Rationalizer] {
+	return func(yield func(Rationalizer) bool) {
+		if start.Denominator() == 0 || stop.Denominator() == 0 || n < 1 {
+			return
+		}
+		first := toBig(start)
+		span := new(big.Rat).Sub(toBig(stop), first)
+		if n > 1 {
+			span.Quo(span, big.NewRat(int64(n-1), 1))
+		}
+		for i := 0; i < n; i++ {
+			x := new(big.Rat).Mul(span, big.NewRat(int64(i), 1))
+			r, ok := fromBig(x.Add(x, first))
+			if !ok || !yield(r) {
+				return
+			}
+		}
+	}
+}
+
+// toBig converts r to a big.Rat. The denominator must be non-zero.
+func toBig(r Rationalizer) *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(int64(r.Numerator())), big.NewInt(int64(r.Denominator())))
+}
+
+// fromBig converts x back to a Rational in lowest terms. The bool is false
+// if the numerator or denominator does not fit in an int.
+func fromBig(x *big.Rat) (Rational, bool) {
+	a, b := x.Num(), x.Denom()
+	if !fitsInt(a) || !fitsInt(b) {
+		return Rational{0, 1}, false
+	}
+	return Rational{int(a.Int64()), int(b.Int64())}, true
+}
+
+// fromBigErr converts the result of a big-backed helper to a Rational,
+// with an error if it does not fit.
+func fromBigErr(x *big.Rat, err error) (Rationalizer, error) {
+	if err != nil {
+		return Rational{0, 1}, err
+	}
+	r, ok := fromBig(x)
+	if !ok {
+		return Rational{0, 1}, fmt.Errorf("%v overflows int", x.RatString())
+	}
+	return r, nil
+}
+
+// Geometric sum a + a*r + ... + a*r^(n-1). See GeometricSumBig; the error
+// is also non-nil if the sum does not fit in a Rational.
+func GeometricSum(a, r Rationalizer, n int) (Rationalizer, error) {
+	return fromBigErr(GeometricSumBig(a, r, n))
+}
+
+// GeometricSumBig evaluates a + a*r + ... + a*r^(n-1) exactly with the
+// closed form a*(1-r^n)/(1-r), or n*a when r is 1. The sum is 0 for
+// n <= 0. The error is non-nil if a or r has a zero denominator.
+func GeometricSumBig(a, r Rationalizer, n int) (*big.Rat, error) {
+	if a.Denominator() == 0 || r.Denominator() == 0 {
+		return nil, errors.New("denominator cannot be zero")
+	}
+	if n <= 0 {
+		return new(big.Rat), nil
+	}
+	ba, br := toBig(a), toBig(r)
+	one := big.NewRat(1, 1)
+	if br.Cmp(one) == 0 {
+		return ba.Mul(ba, big.NewRat(int64(n), 1)), nil
+	}
+
+	bn := big.NewInt(int64(n))
+	rn := new(big.Rat).SetFrac(new(big.Int).Exp(br.Num(), bn, nil), new(big.Int).Exp(br.Denom(), bn, nil))
+	num := new(big.Rat).Sub(one, rn)
+	den := new(big.Rat).Sub(one, br)
+	sum := new(big.Rat).Mul(ba, num)
+	return sum.Quo(sum, den), nil
+}
+
+// Arithmetic sum a + (a+d) + ... + (a+(n-1)*d). See ArithmeticSumBig; the
+// error is also non-nil if the sum does not fit in a Rational.
+func ArithmeticSum(a, d Rationalizer, n int) (Rationalizer, error) {
+	return fromBigErr(ArithmeticSumBig(a, d, n))
+}
+
+// ArithmeticSumBig evaluates the n-term arithmetic series starting at a
+// with step d as n*a + n*(n-1)/2*d. The sum is 0 for n <= 0. The error is
+// non-nil if a or d has a zero denominator.
+func ArithmeticSumBig(a, d Rationalizer, n int) (*big.Rat, error) {
+	if a.Denominator() == 0 || d.Denominator() == 0 {
+		return nil, errors.New("denominator cannot be zero")
+	}
+	if n <= 0 {
+		return new(big.Rat), nil
+	}
+	bn := big.NewRat(int64(n), 1)
+	sum := new(big.Rat).Mul(toBig(a), bn)
+	steps := new(big.Rat).Mul(bn, big.NewRat(int64(n-1), 2))
+	return sum.Add(sum, steps.Mul(steps, toBig(d))), nil
+}
+
+// FitWithin returns the largest width and height no bigger than maxW by
+// maxH whose ratio is exactly srcW:srcH, together with the exact scale
+// factor w/srcW. It scales up as well as down. If no such size exists,
+// including for non-positive arguments, it returns 0, 0 and a scale of 0/1.
+func FitWithin(srcW, srcH, maxW, maxH int) (w, h int, scale Rational) {
+	if srcW <= 0 || srcH <= 0 || maxW <= 0 || maxH <= 0 {
+		return 0, 0, Rational{0, 1}
+	}
+	gcd := GCD(srcW, srcH)
+	unitW, unitH := srcW/gcd, srcH/gcd
+
+	k := maxW / unitW
+	if maxH/unitH < k {
+		k = maxH / unitH
+	}
+	if k == 0 {
+		return 0, 0, Rational{0, 1}
+	}
+	return k * unitW, k * unitH, canonical(Rational{k, gcd})
+}
+
 // insertion sort for int
 func insertionSortInt(a []int) []int {
 	n := len(a)
@@ -239,6 +570,27 @@ func randStr(n int) string {
 }
 
 func main() {
+	stress := flag.Bool("stress", false, "run randomized differential testing against math/big instead of the sorting benchmark")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed for -stress")
+	chains := flag.Int("chains", 1000, "number of operation chains for -stress")
+	steps := flag.Int("steps", 100, "operations per chain for -stress")
+	maxOperand := flag.Int("max", 100, "largest operand numerator/denominator for -stress (1 to 2147483647)")
+	flag.Parse()
+
+	if *stress {
+		if *maxOperand < 1 || *maxOperand > math.MaxInt32 {
+			fmt.Fprintln(os.Stderr, "-max must be between 1 and", math.MaxInt32)
+			os.Exit(2)
+		}
+		cfg := stressConfig{seed: *seed, chains: *chains, steps: *steps, maxOperand: *maxOperand}
+		if failures := runStress(cfg, os.Stdout); failures > 0 {
+			fmt.Printf("%v of %v chains diverged (seed %v)\n", failures, cfg.chains, cfg.seed)
+			os.Exit(1)
+		}
+		fmt.Printf("%v chains of %v steps agreed with math/big (seed %v)\n", cfg.chains, cfg.steps, cfg.seed)
+		return
+	}
+
 	// average for different n
 	average_int := make([]float64, 10)
 	average_str := make([]float64, 10)
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"iter"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestGCDLCMRational(t *testing.T) {
+	tests := []struct {
+		in       []Rationalizer
+		gcd, lcm Rational
+	}{
+		{nil, Rational{0, 1}, Rational{0, 1}},
+		{[]Rationalizer{Rational{3, 8}, Rational{5, 12}}, Rational{1, 24}, Rational{15, 4}},
+		{[]Rationalizer{Rational{1, 2}, Rational{1, 3}, Rational{-3, 4}}, Rational{1, 12}, Rational{3, 1}},
+		{[]Rationalizer{Rational{2, -4}, Rational{6, 8}}, Rational{1, 4}, Rational{3, 2}},
+		{[]Rationalizer{Rational{0, 1}, Rational{2, 3}}, Rational{2, 3}, Rational{0, 1}},
+	}
+	for _, tt := range tests {
+		gcd, err := GCDRational(tt.in)
+		if err != nil || gcd != tt.gcd {
+			t.Errorf("GCDRational(%v) = %v, %v; want %v", tt.in, gcd, err, tt.gcd)
+		}
+		lcm, err := LCMRational(tt.in)
+		if err != nil || lcm != tt.lcm {
+			t.Errorf("LCMRational(%v) = %v, %v; want %v", tt.in, lcm, err, tt.lcm)
+		}
+	}
+}
+
+func TestGCDLCMRationalErrors(t *testing.T) {
+	primes := []Rationalizer{Rational{1, 1000003}, Rational{1, 1000033}, Rational{1, 1000037}, Rational{1, 1000039}}
+	if r, err := GCDRational(primes); err == nil {
+		t.Errorf("GCDRational(%v) = %v; want overflow error", primes, r)
+	}
+	inverted := []Rationalizer{Rational{1000003, 1}, Rational{1000033, 1}, Rational{1000037, 1}, Rational{1000039, 1}}
+	if r, err := LCMRational(inverted); err == nil {
+		t.Errorf("LCMRational(%v) = %v; want overflow error", inverted, r)
+	}
+	if l, err := LCM(1<<62, 3); err == nil {
+		t.Errorf("LCM(1<<62, 3) = %v; want overflow error", l)
+	}
+
+	zero := []Rationalizer{Rational{1, 2}, Rational{1, 0}}
+	if r, err := GCDRational(zero); err == nil {
+		t.Errorf("GCDRational(%v) = %v; want error", zero, r)
+	}
+	if r, err := LCMRational(zero); err == nil {
+		t.Errorf("LCMRational(%v) = %v; want error", zero, r)
+	}
+}
+
+func TestSeriesSums(t *testing.T) {
+	tests := []struct {
+		name string
+		sum  func(a, b Rationalizer, n int) (Rationalizer, error)
+		a, b Rational
+		n    int
+		want Rational
+	}{
+		{"geometric", GeometricSum, Rational{1, 1}, Rational{1, 2}, 10, Rational{1023, 512}},
+		{"geometric r=1", GeometricSum, Rational{3, 1}, Rational{1, 1}, 4, Rational{12, 1}},
+		{"geometric r<0", GeometricSum, Rational{1, 1}, Rational{-2, 1}, 3, Rational{3, 1}},
+		{"geometric n=0", GeometricSum, Rational{1, 1}, Rational{1, 2}, 0, Rational{0, 1}},
+		{"arithmetic", ArithmeticSum, Rational{1, 1}, Rational{1, 1}, 100, Rational{5050, 1}},
+		{"arithmetic fractional", ArithmeticSum, Rational{1, 2}, Rational{1, 3}, 3, Rational{5, 2}},
+		{"arithmetic n=0", ArithmeticSum, Rational{1, 2}, Rational{1, 3}, 0, Rational{0, 1}},
+	}
+	for _, tt := range tests {
+		got, err := tt.sum(tt.a, tt.b, tt.n)
+		if err != nil || got != tt.want {
+			t.Errorf("%v(%v, %v, %v) = %v, %v; want %v", tt.name, tt.a, tt.b, tt.n, got, err, tt.want)
+		}
+	}
+}
+
+func TestGeometricSumOverflow(t *testing.T) {
+	if r, err := GeometricSum(Rational{1, 1}, Rational{1, 3}, 50); err == nil {
+		t.Errorf("GeometricSum(1/1, 1/3, 50) = %v; want overflow error", r)
+	}
+	x, err := GeometricSumBig(Rational{1, 1}, Rational{1, 3}, 50)
+	if err != nil {
+		t.Fatalf("GeometricSumBig(1/1, 1/3, 50) error: %v", err)
+	}
+	// 3/2 * (1 - 3^-50)
+	pow := new(big.Int).Exp(big.NewInt(3), big.NewInt(50), nil)
+	want := new(big.Rat).SetFrac(new(big.Int).Sub(pow, big.NewInt(1)), pow)
+	want.Mul(want, big.NewRat(3, 2))
+	if x.Cmp(want) != 0 {
+		t.Errorf("GeometricSumBig(1/1, 1/3, 50) = %v; want %v", x.RatString(), want.RatString())
+	}
+
+	// 1 + 1/2 + ... + 2^-(n-1) = 2 - 2^(1-n), for an n large enough that
+	// r^n must not be computed one multiplication at a time.
+	n := 1 << 20
+	x, err = GeometricSumBig(Rational{1, 1}, Rational{1, 2}, n)
+	if err != nil {
+		t.Fatalf("GeometricSumBig(1/1, 1/2, %v) error: %v", n, err)
+	}
+	want = new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), uint(n-1)))
+	want.Sub(big.NewRat(2, 1), want)
+	if x.Cmp(want) != 0 {
+		t.Errorf("GeometricSumBig(1/1, 1/2, %v) is not 2 - 2^(1-n)", n)
+	}
+	for _, m := range []int{0, -3} {
+		if x, err := GeometricSumBig(Rational{1, 1}, Rational{1, 2}, m); err != nil || x.Sign() != 0 {
+			t.Errorf("GeometricSumBig(1/1, 1/2, %v) = %v, %v; want 0", m, x, err)
+		}
+		if x, err := ArithmeticSumBig(Rational{1, 1}, Rational{1, 2}, m); err != nil || x.Sign() != 0 {
+			t.Errorf("ArithmeticSumBig(1/1, 1/2, %v) = %v, %v; want 0", m, x, err)
+		}
+	}
+
+	if _, err := GeometricSum(Rational{1, 0}, Rational{1, 2}, 3); err == nil {
+		t.Errorf("GeometricSum with zero denominator: want error")
+	}
+	if _, err := ArithmeticSum(Rational{1, 2}, Rational{1, 0}, 3); err == nil {
+		t.Errorf("ArithmeticSum with zero denominator: want error")
+	}
+}
+
+// collect ranges over seq, stopping after limit values.
+func collect(seq iter.Seq[Rationalizer], limit int) []string {
+	var out []string
+	for r := range seq {
+		out = append(out, r.String())
+		if len(out) == limit {
+			break
+		}
+	}
+	return out
+}
+
+func TestSequences(t *testing.T) {
+	tests := []struct {
+		name  string
+		seq   iter.Seq[Rationalizer]
+		limit int
+		want  string
+	}{
+		{"HarmonicPartialSums", HarmonicPartialSums(4), 10, "1/1 3/2 11/6 25/12"},
+		{"HarmonicPartialSums early", HarmonicPartialSums(1000), 2, "1/1 3/2"},
+		{"Farey", Farey(5), 20, "0/1 1/5 1/4 1/3 2/5 1/2 3/5 2/3 3/4 4/5 1/1"},
+		{"Farey order 1", Farey(1), 20, "0/1 1/1"},
+		{"Farey early", Farey(1000), 3, "0/1 1/1000 1/999"},
+		{"CalkinWilf early", CalkinWilf(), 10, "1/1 1/2 2/1 1/3 3/2 2/3 3/1 1/4 4/3 3/5"},
+		{"Convergents", Convergents(Rational{415, 93}), 10, "4/1 9/2 58/13 415/93"},
+		{"Convergents negative", Convergents(Rational{7, -3}), 10, "-3/1 -2/1 -7/3"},
+		{"Convergents integer", Convergents(Rational{6, 3}), 10, "2/1"},
+		{"Convergents early", Convergents(Rational{415, 93}), 2, "4/1 9/2"},
+		{"Linspace", Linspace(Rational{0, 1}, Rational{1, 1}, 5), 10, "0/1 1/4 1/2 3/4 1/1"},
+		{"Linspace descending", Linspace(Rational{1, 2}, Rational{-1, 2}, 3), 10, "1/2 0/1 -1/2"},
+		{"Linspace single", Linspace(Rational{2, 4}, Rational{1, 1}, 1), 10, "1/2"},
+		{"Linspace early", Linspace(Rational{0, 1}, Rational{1, 1}, 1000000), 3, "0/1 1/999999 2/999999"},
+		{"Linspace unreduced", Linspace(Rational{1, -2}, Rational{6, 4}, 3), 10, "-1/2 1/2 3/2"},
+		{"Linspace overflow", Linspace(Rational{1, 3}, Rational{math.MaxInt64, 1}, 3), 10, "1/3"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(collect(tt.seq, tt.limit), " "); got != tt.want {
+			t.Errorf("%v = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+
+	for name, seq := range map[string]iter.Seq[Rationalizer]{
+		"Farey(0)":          Farey(0),
+		"Convergents(1/0)":  Convergents(Rational{1, 0}),
+		"Linspace(1/0, 1)":  Linspace(Rational{1, 0}, Rational{1, 1}, 3),
+		"Linspace(0, 1, 0)": Linspace(Rational{0, 1}, Rational{1, 1}, 0),
+	} {
+		if got := collect(seq, 10); len(got) != 0 {
+			t.Errorf("%v = %v; want nothing", name, got)
+		}
+	}
+}
+
+func TestFloat64ReportExact(t *testing.T) {
+	for _, tt := range []struct {
+		r Rational
+		f float64
+	}{
+		{Rational{1, 2}, 0.5},
+		{Rational{3, 4}, 0.75},
+		{Rational{-5, 8}, -0.625},
+		{Rational{6, 3}, 2},
+		{Rational{3, -4}, -0.75},
+		{Rational{0, 7}, 0},
+	} {
+		f, err, exact := tt.r.Float64Report()
+		if f != tt.f || err != (Rational{0, 1}) || !exact {
+			t.Errorf("%v.Float64Report() = %v, %v, %v; want %v, 0/1, true", tt.r, f, err, exact, tt.f)
+		}
+		f, bigErr, exact := tt.r.Float64ReportBig()
+		if f != tt.f || bigErr == nil || bigErr.Sign() != 0 || !exact {
+			t.Errorf("%v.Float64ReportBig() = %v, %v, %v; want %v, 0, true", tt.r, f, bigErr, exact, tt.f)
+		}
+	}
+}
+
+func TestFloat64ReportInexact(t *testing.T) {
+	// Errors that fit in a Rational, including the last denominator of the
+	// form 1/b that does.
+	for _, tt := range []struct {
+		r, err Rational
+	}{
+		{Rational{1, 3}, Rational{-1, 54043195528445952}},
+		{Rational{1, 44}, Rational{1, 1585267068834414592}},
+		{Rational{1<<62 + 1, 1}, Rational{-1, 1}},
+	} {
+		f, err, exact := tt.r.Float64Report()
+		if err != tt.err || exact {
+			t.Errorf("%v.Float64Report() = %v, %v, %v; want err %v, inexact", tt.r, f, err, exact, tt.err)
+		}
+	}
+
+	// Errors that overflow Float64Report but are exact in Float64ReportBig.
+	for _, r := range []Rational{{1, 45}, {1, 49}, {1, 100}, {1, 1000}, {7, 1000}} {
+		f, err, exact := r.Float64Report()
+		if err != (Rational{0, 0}) || exact {
+			t.Errorf("%v.Float64Report() = %v, %v, %v; want 0/0, inexact", r, f, err, exact)
+		}
+		f, bigErr, exact := r.Float64ReportBig()
+		if bigErr == nil || exact {
+			t.Fatalf("%v.Float64ReportBig() = %v, %v, %v; want an error value", r, f, bigErr, exact)
+		}
+		// f - r == err exactly.
+		back := new(big.Rat).SetFloat64(f)
+		if back.Sub(back, toBig(r)).Cmp(bigErr) != 0 {
+			t.Errorf("%v.Float64ReportBig() error %v is not f - r", r, bigErr.RatString())
+		}
+	}
+
+	if _, err, exact := (Rational{1, 0}).Float64Report(); err != (Rational{0, 0}) || exact {
+		t.Errorf("1/0.Float64Report() = %v, %v; want 0/0, inexact", err, exact)
+	}
+	if _, err, exact := (Rational{1, 0}).Float64ReportBig(); err != nil || exact {
+		t.Errorf("1/0.Float64ReportBig() = %v, %v; want nil, inexact", err, exact)
+	}
+}
+
+func TestFitWithin(t *testing.T) {
+	tests := []struct {
+		srcW, srcH, maxW, maxH int
+		w, h                   int
+		scale                  Rational
+	}{
+		{1920, 1080, 1000, 1000, 992, 558, Rational{31, 60}},
+		{1920, 1080, 1920, 1080, 1920, 1080, Rational{1, 1}},
+		{16, 9, 1920, 1080, 1920, 1080, Rational{120, 1}}, // upscaling
+		{100, 50, 1000, 1000, 1000, 500, Rational{10, 1}}, // width limits
+		{50, 100, 1000, 300, 150, 300, Rational{3, 1}},    // height limits
+		{4, 6, 5, 100, 4, 6, Rational{1, 1}},              // 2:3 steps of (2, 3)
+		{1000, 1500, 5, 100, 4, 6, Rational{1, 250}},      // reduced by gcd 500
+		{3, 7, 2, 100, 0, 0, Rational{0, 1}},              // 3:7 cannot fit
+		{1001, 1000, 1000, 1000, 0, 0, Rational{0, 1}},    // nor 1001:1000
+		{0, 10, 100, 100, 0, 0, Rational{0, 1}},
+		{10, -1, 100, 100, 0, 0, Rational{0, 1}},
+		{10, 10, 0, 100, 0, 0, Rational{0, 1}},
+		{10, 10, 100, -5, 0, 0, Rational{0, 1}},
+	}
+	for _, tt := range tests {
+		w, h, scale := FitWithin(tt.srcW, tt.srcH, tt.maxW, tt.maxH)
+		if w != tt.w || h != tt.h || scale != tt.scale {
+			t.Errorf("FitWithin(%v, %v, %v, %v) = %v, %v, %v; want %v, %v, %v",
+				tt.srcW, tt.srcH, tt.maxW, tt.maxH, w, h, scale, tt.w, tt.h, tt.scale)
+		}
+		if w == 0 {
+			continue
+		}
+		// The scale is exactly w/srcW and h/srcH, and the ratio is preserved.
+		if !sameValue(scale, Rational{w, tt.srcW}) || !sameValue(scale, Rational{h, tt.srcH}) {
+			t.Errorf("FitWithin(%v, %v, %v, %v) scale %v is not %v/%v and %v/%v",
+				tt.srcW, tt.srcH, tt.maxW, tt.maxH, scale, w, tt.srcW, h, tt.srcH)
+		}
+	}
+}
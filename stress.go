@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+)
+
+// Settings for a randomized differential run against math/big.Rat.
+type stressConfig struct {
+	seed       int64
+	chains     int
+	steps      int
+	maxOperand int // numerators and denominators are drawn from [-maxOperand, maxOperand]
+}
+
+// A single stress step: cur <op> operand.
+type stressStep struct {
+	op      string
+	cur     Rational
+	operand Rational
+}
+
+// Go source that reproduces the step on its own.
+func (s stressStep) reproducer() string {
+	switch s.op {
+	case "Invert":
+		return fmt.Sprintf("Rational{%v, %v}.Invert()", s.cur.numerator, s.cur.denominator)
+	default:
+		return fmt.Sprintf("Rational{%v, %v}.%v(Rational{%v, %v})",
+			s.cur.numerator, s.cur.denominator, s.op, s.operand.numerator, s.operand.denominator)
+	}
+}
+
+var stressOps = []string{"Add", "Multiply", "Divide", "Invert", "Equal", "LessThan"}
+
+// runStress performs cfg.chains chains of cfg.steps random operations,
+// checking each Rational result against math/big.Rat. Every chain continues
+// from the Rational result, so the first divergence is always reproducible
+// from the single step that produced it. It writes a reproducer for each
+// diverging chain to w and returns the number of divergences.
+// cfg.maxOperand must be between 1 and math.MaxInt32.
+func runStress(cfg stressConfig, w io.Writer) int {
+	rng := rand.New(rand.NewSource(cfg.seed))
+	random := func() Rational {
+		for {
+			a := rng.Intn(2*cfg.maxOperand+1) - cfg.maxOperand
+			b := rng.Intn(2*cfg.maxOperand+1) - cfg.maxOperand
+			if b != 0 {
+				return Rational{a, b}
+			}
+		}
+	}
+
+	failures := 0
+	for c := 0; c < cfg.chains; c++ {
+		cur := random()
+		for i := 0; i < cfg.steps; i++ {
+			step := stressStep{stressOps[rng.Intn(len(stressOps))], cur, random()}
+			next, msg := checkStep(step)
+			if msg != "" {
+				failures++
+				fmt.Fprintf(w, "seed %v, chain %v, step %v: %v\n\t%v\n",
+					cfg.seed, c, i, msg, step.reproducer())
+				break
+			}
+			cur = next
+		}
+	}
+	return failures
+}
+
+// checkStep runs step on Rational and on big.Rat. It returns the Rational
+// result to continue the chain with, or a non-empty message on divergence.
+func checkStep(step stressStep) (next Rational, msg string) {
+	defer func() {
+		if p := recover(); p != nil {
+			msg = fmt.Sprintf("panic: %v", p)
+		}
+	}()
+
+	x, y := toBig(step.cur), toBig(step.operand)
+	var got Rationalizer
+	var err error
+	want := new(big.Rat)
+	next = step.cur
+
+	switch step.op {
+	case "Add":
+		got = step.cur.Add(step.operand)
+		want.Add(x, y)
+	case "Multiply":
+		got = step.cur.Multiply(step.operand)
+		want.Mul(x, y)
+	case "Divide":
+		got, err = step.cur.Divide(step.operand)
+		if y.Sign() == 0 {
+			if err == nil {
+				return next, fmt.Sprintf("got %v, want division by zero error", got)
+			}
+			return next, ""
+		}
+		want.Quo(x, y)
+	case "Invert":
+		got, err = step.cur.Invert()
+		if x.Sign() == 0 {
+			if err == nil {
+				return next, fmt.Sprintf("got %v, want division by zero error", got)
+			}
+			return next, ""
+		}
+		want.Inv(x)
+	case "Equal":
+		if g, w := step.cur.Equal(step.operand), x.Cmp(y) == 0; g != w {
+			return next, fmt.Sprintf("got %v, want %v", g, w)
+		}
+		return next, ""
+	case "LessThan":
+		if g, w := step.cur.LessThan(step.operand), x.Cmp(y) < 0; g != w {
+			return next, fmt.Sprintf("got %v, want %v", g, w)
+		}
+		return next, ""
+	}
+
+	if err != nil {
+		return next, fmt.Sprintf("unexpected error: %v", err)
+	}
+	if got.Denominator() == 0 {
+		return next, fmt.Sprintf("got %v, want %v", got, want.RatString())
+	}
+	if toBig(got).Cmp(want) != 0 {
+		return next, fmt.Sprintf("got %v, want %v", got, want.RatString())
+	}
+	return Rational{got.Numerator(), got.Denominator()}, ""
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestRunStressFindsOverflow(t *testing.T) {
+	var out bytes.Buffer
+	cfg := stressConfig{seed: 1, chains: 5, steps: 50, maxOperand: 100}
+	failures := runStress(cfg, &out)
+	if failures == 0 {
+		t.Fatalf("runStress(%+v) found no divergence", cfg)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2*failures {
+		t.Fatalf("runStress wrote %v lines for %v failures:\n%v", len(lines), failures, out.String())
+	}
+	for i := 0; i < len(lines); i += 2 {
+		if !strings.HasPrefix(lines[i], "seed 1, chain ") || !strings.Contains(lines[i], ": got ") {
+			t.Errorf("line %v = %q; want a divergence report", i, lines[i])
+		}
+		if !strings.HasPrefix(lines[i+1], "\tRational{") {
+			t.Errorf("line %v = %q; want a reproducer", i+1, lines[i+1])
+		}
+	}
+}
+
+func TestRunStressAgrees(t *testing.T) {
+	// Short chains of small operands stay in range, so nothing diverges.
+	var out bytes.Buffer
+	cfg := stressConfig{seed: 1, chains: 200, steps: 3, maxOperand: 5}
+	if failures := runStress(cfg, &out); failures != 0 {
+		t.Errorf("runStress(%+v) = %v failures:\n%v", cfg, failures, out.String())
+	}
+}
+
+func TestCheckStep(t *testing.T) {
+	tests := []struct {
+		step    stressStep
+		diverge bool
+	}{
+		{stressStep{"Add", Rational{1, 2}, Rational{1, -3}}, false},
+		{stressStep{"Add", Rational{math.MaxInt64, 1}, Rational{1, 1}}, true},
+		{stressStep{"Multiply", Rational{2, -3}, Rational{-3, 4}}, false},
+		{stressStep{"Multiply", Rational{1 << 62, 1}, Rational{4, 1}}, true},
+		{stressStep{"Divide", Rational{1, 2}, Rational{-1, 3}}, false},
+		{stressStep{"Divide", Rational{1 << 62, 1}, Rational{1, 4}}, true},
+		{stressStep{"Divide", Rational{1, 2}, Rational{0, 3}}, false}, // by zero: error expected
+		// Rational.Invert only swaps fields, so there is no known divergence.
+		// The operand is unused but must be valid.
+		{stressStep{"Invert", Rational{-2, 3}, Rational{1, 1}}, false},
+		{stressStep{"Invert", Rational{0, 3}, Rational{1, 1}}, false}, // by zero: error expected
+		{stressStep{"Equal", Rational{1, -2}, Rational{-2, 4}}, false},
+		{stressStep{"Equal", Rational{1, 2}, Rational{1, 3}}, false},
+		// Reducing MinInt64/-1 wraps back to MinInt64, flipping the sign.
+		{stressStep{"Equal", Rational{math.MinInt64, -1}, Rational{math.MinInt64, 1}}, true},
+		{stressStep{"LessThan", Rational{-1, 2}, Rational{1, -3}}, false},
+		// Both sides round to the same float64.
+		{stressStep{"LessThan", Rational{1 << 53, 1}, Rational{1<<53 + 1, 1}}, true},
+	}
+	for _, tt := range tests {
+		_, msg := checkStep(tt.step)
+		if (msg != "") != tt.diverge {
+			t.Errorf("checkStep(%v) = %q; want divergence %v", tt.step.reproducer(), msg, tt.diverge)
+		}
+	}
+}
+
+func TestCheckStepContinuesFromResult(t *testing.T) {
+	next, msg := checkStep(stressStep{"Add", Rational{1, 2}, Rational{1, 3}})
+	if msg != "" || next != (Rational{5, 6}) {
+		t.Errorf("checkStep(1/2 + 1/3) = %v, %q; want 5/6", next, msg)
+	}
+	next, msg = checkStep(stressStep{"Equal", Rational{1, 2}, Rational{1, 2}})
+	if msg != "" || next != (Rational{1, 2}) {
+		t.Errorf("checkStep(1/2 == 1/2) = %v, %q; want chain to stay at 1/2", next, msg)
+	}
+}